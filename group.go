@@ -0,0 +1,161 @@
+package runhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// member is a single server registered with a Group, plus its optional
+// autocert config.
+type member struct {
+	srv    *http.Server
+	crtCfg *AutocertConfig
+}
+
+// Group supervises multiple *http.Server instances (each with its own
+// optional AutocertConfig), running them together until a signal is
+// received or any one of them fails - analogous to RunServer, but for
+// deployments that need more than one listener (e.g. a public API, an
+// admin/metrics port, and an HTTP redirect server).
+type Group struct {
+	opts    *RunOptions
+	members []member
+
+	onServe    func()
+	onError    func(error)
+	onShutdown func()
+
+	// IgnoredErrors lists errors that should not fail Run, in addition to
+	// the always-ignored http.ErrServerClosed.
+	IgnoredErrors []error
+}
+
+// NewGroup creates an empty Group. opts controls the graceful shutdown
+// deadlines applied to every member server and may be nil to use the
+// RunServer defaults.
+func NewGroup(opts *RunOptions) *Group {
+	return &Group{opts: opts}
+}
+
+// Add registers srv (and its optional crtCfg) with the group. It must be
+// called before Run.
+func (g *Group) Add(srv *http.Server, crtCfg *AutocertConfig) {
+	g.members = append(g.members, member{srv: srv, crtCfg: crtCfg})
+}
+
+// OnServe registers a hook called once, right before the group starts
+// listening/serving.
+func (g *Group) OnServe(fn func()) {
+	g.onServe = fn
+}
+
+// OnError registers a hook called with any member's non-ignored error.
+func (g *Group) OnError(fn func(error)) {
+	g.onError = fn
+}
+
+// OnShutdown registers a hook called once shutdown has been triggered
+// (signal or a member's failure), before the group waits for completion.
+func (g *Group) OnShutdown(fn func()) {
+	g.onShutdown = fn
+}
+
+// Run starts every registered server and blocks until one of g.opts.Signals
+// is received (SIGTERM/SIGINT, plus SIGHUP on Unix, by default) or any one
+// of them fails - whichever happens first triggers graceful shutdown of the
+// rest, within the same window. Handlers can call context.Cause on their
+// request's context to tell a received signal, a sibling server's failure
+// and a parent cancellation apart.
+func (g *Group) Run(ctx context.Context) error {
+	// track *why* we eventually shut down: signal vs. sibling failure vs.
+	// the parent ctx being cancelled by the caller
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, g.opts.signals()...)
+	defer signal.Stop(sigCh)
+
+	// manage server threads - let them cancel each other
+	threads, ctx := errgroup.WithContext(ctx)
+
+	threads.Go(func() error {
+		select {
+		case sig := <-sigCh:
+			cancel(fmt.Errorf("%w: %s", ErrSignalled, sig))
+		case <-ctx.Done():
+		}
+		return nil
+	})
+
+	if g.onServe != nil {
+		g.onServe()
+	}
+
+	for _, m := range g.members {
+		listenAndServe, challengeSrv := withAutocert(m.srv, m.crtCfg)
+		applyServerDefaults(m.srv, g.opts)
+
+		// use the lower-most context for clients - ASAP-closeable
+		m.srv.BaseContext = func(net.Listener) context.Context { return ctx }
+
+		threads.Go(shutdowner(ctx, m.srv, g.opts))
+		threads.Go(g.guard(cancel, listenAndServe))
+
+		if challengeSrv != nil {
+			applyServerDefaults(challengeSrv, g.opts)
+
+			threads.Go(shutdowner(ctx, challengeSrv, g.opts))
+			threads.Go(g.guard(cancel, challengeSrv.ListenAndServe))
+		}
+	}
+
+	threads.Go(func() error {
+		<-ctx.Done()
+		if g.onShutdown != nil {
+			g.onShutdown()
+		}
+		return nil
+	})
+
+	// wait for completion (failure or proper signal-triggered shutdown)
+	if err := threads.Wait(); err != nil && !g.ignored(err) {
+		return err
+	}
+	return nil
+}
+
+// guard wraps fn so that its error, if any, is reported via OnError and
+// recorded as the shutdown cause before being returned to the errgroup
+// (which uses it to cancel the rest).
+func (g *Group) guard(cancel context.CancelCauseFunc, fn func() error) func() error {
+	return func() error {
+		err := fn()
+		if err != nil && !g.ignored(err) {
+			cancel(err)
+			if g.onError != nil {
+				g.onError(err)
+			}
+		}
+		return err
+	}
+}
+
+func (g *Group) ignored(err error) bool {
+	if errors.Is(err, http.ErrServerClosed) {
+		return true
+	}
+	for _, ignored := range g.IgnoredErrors {
+		if errors.Is(err, ignored) {
+			return true
+		}
+	}
+	return false
+}
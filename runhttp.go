@@ -3,64 +3,211 @@ package runhttp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"os/signal"
-	"syscall"
 	"time"
 
 	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
 	"golang.org/x/sync/errgroup"
 )
 
+// ErrSignalled is wrapped into the cancellation cause exposed via
+// context.Cause(srv.BaseContext(...)) when a server shuts down because of a
+// received signal, as opposed to a sibling server's failure or the parent
+// context being cancelled by the caller.
+var ErrSignalled = errors.New("runhttp: received signal")
+
 // AutocertConfig holds basic autocert.Manager config.
 type AutocertConfig struct {
-	Domain   string
+	// Domains is the set of domains certificates may be issued for, used
+	// to build a HostPolicy via autocert.HostWhitelist. Ignored if
+	// HostPolicy is set.
+	Domains []string
+
+	// CacheDir is a shortcut for Cache: autocert.DirCache(CacheDir).
+	// Ignored if Cache is set.
 	CacheDir string
+
+	// Cache persists issued certificates, e.g. for horizontally-scaled
+	// deployments where local disk is ephemeral (Redis/S3/GCS-backed
+	// implementations etc). Defaults to CacheDir, if set.
+	Cache autocert.Cache
+
+	// HostPolicy allows fully dynamic domain policies. Defaults to
+	// autocert.HostWhitelist(Domains...), if unset.
+	HostPolicy autocert.HostPolicy
+}
+
+// Defaults applied to a *http.Server's zero-valued fields, unless
+// overridden via RunOptions - chosen to be Slowloris-resistant without
+// capping request/response duration, since handlers may legitimately
+// stream (SSE, long-poll, WebSocket). ReadTimeout/WriteTimeout are
+// deliberately left at their stdlib zero-value (no deadline) for the same
+// reason; set them via RunOptions if your workload has no streaming needs.
+const (
+	defaultShutdownTimeout   = 3 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = 1 << 20 // 1MB
+)
+
+// RunOptions configures the graceful shutdown and the *http.Server defaults
+// applied by RunServer. A nil *RunOptions (or a zero-valued one) uses the
+// defaults documented on each field.
+type RunOptions struct {
+	// ShutdownTimeout is the graceful drain period for in-flight requests.
+	// srv.Shutdown is given ShutdownTimeout+KillTimeout in total before
+	// the server is force-closed. Defaults to 3s.
+	ShutdownTimeout time.Duration
+
+	// KillTimeout is additional time allowed, on top of ShutdownTimeout,
+	// before the server is force-closed via srv.Close(), for handlers
+	// that ignore their request context (long-poll/SSE/WebSocket etc).
+	// Defaults to 0 - i.e. force-close as soon as ShutdownTimeout elapses.
+	KillTimeout time.Duration
+
+	// ReadHeaderTimeout and IdleTimeout are applied to any zero-valued
+	// field of the same name on the *http.Server passed to RunServer.
+	// Leave a field at its zero value here to use the corresponding
+	// default; set the *http.Server's own field directly to opt out of a
+	// given default entirely.
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// ReadTimeout and WriteTimeout, if set, are likewise applied to any
+	// zero-valued field of the same name on the *http.Server. Unlike the
+	// fields above, they have no default - http.Server's own zero value
+	// (no deadline) is preserved unless you opt in here, since a blanket
+	// deadline would cut off legitimately long-lived streaming responses
+	// (SSE, long-poll, WebSocket).
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// Signals is the set of signals that trigger graceful shutdown.
+	// Defaults to SIGTERM and SIGINT (plus SIGHUP on Unix).
+	Signals []os.Signal
+}
+
+func (o *RunOptions) shutdownTimeout() time.Duration {
+	if o == nil || o.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return o.ShutdownTimeout
+}
+
+func (o *RunOptions) killTimeout() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.KillTimeout
+}
+
+func (o *RunOptions) readHeaderTimeout() time.Duration {
+	if o == nil || o.ReadHeaderTimeout <= 0 {
+		return defaultReadHeaderTimeout
+	}
+	return o.ReadHeaderTimeout
+}
+
+func (o *RunOptions) readTimeout() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.ReadTimeout
+}
+
+func (o *RunOptions) writeTimeout() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.WriteTimeout
+}
+
+func (o *RunOptions) idleTimeout() time.Duration {
+	if o == nil || o.IdleTimeout <= 0 {
+		return defaultIdleTimeout
+	}
+	return o.IdleTimeout
+}
+
+func (o *RunOptions) maxHeaderBytes() int {
+	if o == nil || o.MaxHeaderBytes <= 0 {
+		return defaultMaxHeaderBytes
+	}
+	return o.MaxHeaderBytes
+}
+
+func (o *RunOptions) signals() []os.Signal {
+	if o == nil || len(o.Signals) == 0 {
+		return defaultSignals()
+	}
+	return o.Signals
 }
 
 // RunServer runs the provided lightly-configured server (Addr + Handler),
 // augmenting it with sane defaults,
-// until SIGTERM is received.
-func RunServer(ctx context.Context, srv *http.Server, crtCfg *AutocertConfig) error {
-	listenAndServe := srv.ListenAndServe
-
-	// set up TLS using autocert
-	if crtCfg != nil {
-		m := &autocert.Manager{
-			Prompt:     autocert.AcceptTOS,
-			HostPolicy: autocert.HostWhitelist(crtCfg.Domain),
-			Cache:      autocert.DirCache(crtCfg.CacheDir),
-		}
-		srv.TLSConfig = m.TLSConfig()
-		listenAndServe = func() error { return srv.ListenAndServeTLS("", "") }
+// until one of opts.Signals is received (SIGTERM/SIGINT, plus SIGHUP on
+// Unix, by default).
+//
+// If crtCfg is set, srv is additionally served over TLS using autocert, and
+// a plaintext :http server is started alongside it to answer ACME HTTP-01
+// challenges (and redirect everything else to HTTPS) - this is required for
+// certificate issuance/renewal on networks that block inbound :443.
+//
+// opts controls the graceful shutdown deadlines, the signal set and the
+// *http.Server defaults applied to srv, and may be nil to use the defaults
+// throughout. Handlers can call context.Cause on their request's context to
+// tell a received signal, a sibling server's failure and a parent
+// cancellation apart.
+func RunServer(ctx context.Context, srv *http.Server, crtCfg *AutocertConfig, opts *RunOptions) error {
+	listenAndServe, challengeSrv := withAutocert(srv, crtCfg)
+	applyServerDefaults(srv, opts)
+	if challengeSrv != nil {
+		applyServerDefaults(challengeSrv, opts)
 	}
 
-	// listen for signals
-	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGTERM)
-	defer cancel()
+	// track *why* we eventually shut down: signal vs. sibling failure vs.
+	// the parent ctx being cancelled by the caller
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, opts.signals()...)
+	defer signal.Stop(sigCh)
 
 	// manage server threads - let them cancel each other
 	threads, ctx := errgroup.WithContext(ctx)
 
+	threads.Go(func() error {
+		select {
+		case sig := <-sigCh:
+			cancel(fmt.Errorf("%w: %s", ErrSignalled, sig))
+		case <-ctx.Done():
+		}
+		return nil
+	})
+
 	// use the lower-most context for clients - ASAP-closeable
 	srv.BaseContext = func(net.Listener) context.Context { return ctx }
 
 	// graceful server shutdown
-	threads.Go(func() error {
-		defer srv.Close() // force-close the server in the end
-
-		<-ctx.Done() // block till parent ctx is cancelled (signal or listen failure)
+	threads.Go(shutdowner(ctx, srv, opts))
 
-		// give it a short while for graceful shutdown - to shake off connections etc
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
+	// listen/serve
+	threads.Go(causer(cancel, listenAndServe))
 
-		return srv.Shutdown(ctx)
-	})
+	if challengeSrv != nil {
+		// graceful challenge server shutdown
+		threads.Go(shutdowner(ctx, challengeSrv, opts))
 
-	// listen/serve
-	threads.Go(listenAndServe)
+		// listen/serve challenges
+		threads.Go(causer(cancel, challengeSrv.ListenAndServe))
+	}
 
 	// wait for completion (failure or proper signal-triggered shutdown)
 	if err := threads.Wait(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -68,3 +215,121 @@ func RunServer(ctx context.Context, srv *http.Server, crtCfg *AutocertConfig) er
 	}
 	return nil
 }
+
+// withAutocert wires srv up for TLS via autocert, if crtCfg is set,
+// returning the listenAndServe func to use for srv and, if applicable, a
+// plaintext server answering ACME HTTP-01 challenges. If crtCfg is nil,
+// listenAndServe is simply srv.ListenAndServe and challengeSrv is nil.
+func withAutocert(srv *http.Server, crtCfg *AutocertConfig) (listenAndServe func() error, challengeSrv *http.Server) {
+	if crtCfg == nil {
+		return srv.ListenAndServe, nil
+	}
+
+	hostPolicy := crtCfg.HostPolicy
+	if hostPolicy == nil {
+		hostPolicy = autocert.HostWhitelist(crtCfg.Domains...)
+	}
+
+	cache := crtCfg.Cache
+	if cache == nil && crtCfg.CacheDir != "" {
+		cache = autocert.DirCache(crtCfg.CacheDir)
+	}
+
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: hostPolicy,
+		Cache:      cache,
+	}
+	srv.TLSConfig = m.TLSConfig()
+
+	// answer ACME HTTP-01 challenges, redirect everything else to HTTPS
+	challengeSrv = &http.Server{
+		Addr:    ":http",
+		Handler: m.HTTPHandler(nil),
+	}
+
+	return func() error { return srv.ListenAndServeTLS("", "") }, challengeSrv
+}
+
+// applyServerDefaults populates srv's zero-valued timeout/size fields with
+// opts' (or the package's) defaults, and configures it for HTTP/2 so that
+// Shutdown sends a graceful GOAWAY instead of dropping active streams.
+func applyServerDefaults(srv *http.Server, opts *RunOptions) {
+	if srv.ReadHeaderTimeout == 0 {
+		srv.ReadHeaderTimeout = opts.readHeaderTimeout()
+	}
+	if srv.ReadTimeout == 0 {
+		srv.ReadTimeout = opts.readTimeout()
+	}
+	if srv.WriteTimeout == 0 {
+		srv.WriteTimeout = opts.writeTimeout()
+	}
+	if srv.IdleTimeout == 0 {
+		srv.IdleTimeout = opts.idleTimeout()
+	}
+	if srv.MaxHeaderBytes == 0 {
+		srv.MaxHeaderBytes = opts.maxHeaderBytes()
+	}
+
+	_ = http2.ConfigureServer(srv, nil)
+}
+
+// causer wraps fn so that, should it return a real failure (anything but
+// http.ErrServerClosed), cancel is called with that error as the cause -
+// letting downstream consumers of context.Cause tell a listener failure
+// apart from a signal or a parent cancellation.
+func causer(cancel context.CancelCauseFunc, fn func() error) func() error {
+	return func() error {
+		err := fn()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cancel(err)
+		}
+		return err
+	}
+}
+
+// shutdowner returns a func that blocks till ctx is cancelled (signal or a
+// sibling server's failure), then gracefully shuts srv down within
+// opts.ShutdownTimeout, force-closing it after an additional
+// opts.KillTimeout in case handlers are still holding connections open.
+func shutdowner(ctx context.Context, srv *http.Server, opts *RunOptions) func() error {
+	return func() error {
+		defer srv.Close() // force-close the server in the end
+
+		<-ctx.Done() // block till parent ctx is cancelled (signal or listen failure)
+
+		// give the drain the full ShutdownTimeout+KillTimeout window; the
+		// kill timer below force-closes once that whole window elapses,
+		// so Shutdown must still be around to observe that and return
+		window := opts.shutdownTimeout() + opts.killTimeout()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), window)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() { done <- srv.Shutdown(shutdownCtx) }()
+
+		// force-close stuck connections only once the full
+		// ShutdownTimeout+KillTimeout window elapses, then keep waiting
+		// for Shutdown to actually return
+		kill := time.NewTimer(window)
+		defer kill.Stop()
+
+		select {
+		case err := <-done:
+			return ignoreDeadlineExceeded(err)
+		case <-kill.C:
+			srv.Close()
+			return ignoreDeadlineExceeded(<-done)
+		}
+	}
+}
+
+// ignoreDeadlineExceeded turns the context.DeadlineExceeded that
+// srv.Shutdown returns on a timed-out (forced) shutdown into nil - that is
+// the expected outcome of a KillTimeout-driven force-close, not a failure.
+func ignoreDeadlineExceeded(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
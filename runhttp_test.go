@@ -0,0 +1,68 @@
+package runhttp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestShutdownerRespectsKillTimeout guards against regressing the kill timer
+// back to opts.shutdownTimeout() alone: a handler that ignores its request
+// context must survive until ShutdownTimeout+KillTimeout, not get
+// force-closed at ShutdownTimeout.
+func TestShutdownerRespectsKillTimeout(t *testing.T) {
+	const (
+		shutdownTimeout = 100 * time.Millisecond
+		killTimeout     = 300 * time.Millisecond
+	)
+
+	blocking := make(chan struct{})
+	defer close(blocking) // in case the test fails before the handler unblocks itself
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-blocking // simulate a handler that ignores its request context
+		}),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.Serve(ln)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := &RunOptions{ShutdownTimeout: shutdownTimeout, KillTimeout: killTimeout}
+
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- shutdowner(ctx, srv, opts)() }()
+
+	reqDone := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		reqDone <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond) // give the request time to reach the blocked handler
+
+	start := time.Now()
+	cancel() // trigger shutdown
+
+	if err := <-reqDone; err == nil {
+		t.Fatal("expected the blocked request to be forcibly closed, got nil error")
+	}
+	elapsed := time.Since(start)
+
+	// must not be killed at ShutdownTimeout alone - that's the regression
+	// this test guards against
+	if elapsed < shutdownTimeout+killTimeout/2 {
+		t.Fatalf("connection force-closed too early, after %s (want >= ~%s)", elapsed, shutdownTimeout+killTimeout)
+	}
+
+	<-shutdownDone
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package runhttp
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals is the default signal set RunServer and Group listen for.
+// SIGHUP is Unix-only and not included on this platform.
+func defaultSignals() []os.Signal {
+	return []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+}
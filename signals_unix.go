@@ -0,0 +1,17 @@
+//go:build unix
+
+package runhttp
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals is the default signal set RunServer and Group listen for:
+// SIGTERM, SIGINT and, on Unix, SIGHUP. Each of them triggers the same
+// graceful shutdown; the cause exposed via context.Cause(r.Context()) wraps
+// ErrSignalled and names the received signal, so callers can tell a SIGHUP
+// apart from the others.
+func defaultSignals() []os.Signal {
+	return []os.Signal{syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP}
+}